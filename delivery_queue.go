@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+)
+
+// delivery es un intento de entrega de un Event a un webhook concreto.
+type delivery struct {
+	Id      string `json:"id"`
+	URL     string `json:"url"`
+	Event   Event  `json:"event"`
+	Attempt int    `json:"attempt"`
+}
+
+// deliveryQueue persiste las entregas de webhook pendientes en un fichero
+// JSON, así que si el servidor se reinicia mientras una entrega está
+// esperando su siguiente reintento, no se pierde.
+type deliveryQueue struct {
+	fileName string
+	mu       sync.Mutex
+}
+
+func newDeliveryQueue(fileName string) *deliveryQueue {
+	return &deliveryQueue{fileName: fileName}
+}
+
+func (q *deliveryQueue) load() ([]delivery, error) {
+	content, err := os.ReadFile(q.fileName)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var deliveries []delivery
+	if err := json.Unmarshal(content, &deliveries); err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// save recompacta deliveries mediante escritura-a-temporal-y-rename, igual
+// que json_store.go's flush(), así una caída a mitad de escritura no puede
+// truncar deliveries.json y perder entregas pendientes.
+func (q *deliveryQueue) save(deliveries []delivery) error {
+	data, err := json.MarshalIndent(deliveries, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := q.fileName + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, q.fileName)
+}
+
+func (q *deliveryQueue) add(d delivery) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	deliveries, err := q.load()
+	if err != nil {
+		return err
+	}
+	deliveries = append(deliveries, d)
+	return q.save(deliveries)
+}
+
+func (q *deliveryQueue) update(d delivery) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	deliveries, err := q.load()
+	if err != nil {
+		return err
+	}
+	for i := range deliveries {
+		if deliveries[i].Id == d.Id {
+			deliveries[i] = d
+		}
+	}
+	return q.save(deliveries)
+}
+
+func (q *deliveryQueue) remove(id string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	deliveries, err := q.load()
+	if err != nil {
+		return err
+	}
+
+	filtered := deliveries[:0]
+	for _, d := range deliveries {
+		if d.Id != id {
+			filtered = append(filtered, d)
+		}
+	}
+	return q.save(filtered)
+}
+
+// pending devuelve las entregas que quedaron sin confirmar de una tanda
+// anterior, para reintentarlas al arrancar.
+func (q *deliveryQueue) pending() []delivery {
+	deliveries, err := q.load()
+	if err != nil {
+		return nil
+	}
+	return deliveries
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/vmihailenco/msgpack/v5"
+	"gopkg.in/yaml.v3"
+)
+
+// Encoder representa un formato de representación soportado por la API:
+// sabe tanto serializar las respuestas como interpretar el body de las
+// peticiones en ese formato.
+type Encoder interface {
+	ContentType() string
+	Encode(w io.Writer, v any) error
+	Decode(r io.Reader, v any) error
+}
+
+type jsonEncoder struct{}
+
+func (jsonEncoder) ContentType() string { return "application/json" }
+
+func (jsonEncoder) Encode(w io.Writer, v any) error {
+	// Creamos el encoder para agregar indentación al json
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+func (jsonEncoder) Decode(r io.Reader, v any) error {
+	return json.NewDecoder(r).Decode(v)
+}
+
+type yamlEncoder struct{}
+
+func (yamlEncoder) ContentType() string { return "application/yaml" }
+
+func (yamlEncoder) Encode(w io.Writer, v any) error {
+	return yaml.NewEncoder(w).Encode(v)
+}
+
+func (yamlEncoder) Decode(r io.Reader, v any) error {
+	return yaml.NewDecoder(r).Decode(v)
+}
+
+type msgpackEncoder struct{}
+
+func (msgpackEncoder) ContentType() string { return "application/msgpack" }
+
+func (msgpackEncoder) Encode(w io.Writer, v any) error {
+	return msgpack.NewEncoder(w).Encode(v)
+}
+
+func (msgpackEncoder) Decode(r io.Reader, v any) error {
+	return msgpack.NewDecoder(r).Decode(v)
+}
+
+// encoders registra los formatos soportados por el nombre usado en el
+// parámetro ?format=.
+var encoders = map[string]Encoder{
+	"json":    jsonEncoder{},
+	"yaml":    yamlEncoder{},
+	"msgpack": msgpackEncoder{},
+}
+
+// mediaTypes traduce los Content-Type/Accept aceptados al nombre usado en
+// el mapa encoders.
+var mediaTypes = map[string]string{
+	"application/json":      "json",
+	"application/yaml":      "yaml",
+	"text/yaml":             "yaml",
+	"application/x-yaml":    "yaml",
+	"application/msgpack":   "msgpack",
+	"application/x-msgpack": "msgpack",
+}
+
+// encoderFor decide qué Encoder usar para la petición r: primero mira
+// ?format=, luego el header Accept y por último Content-Type. Si ninguno
+// pide un formato reconocido, se mantiene JSON, el comportamiento histórico
+// de la API.
+func encoderFor(r *http.Request) Encoder {
+	if format := r.URL.Query().Get("format"); format != "" {
+		if enc, ok := encoders[strings.ToLower(format)]; ok {
+			return enc
+		}
+	}
+
+	if accept := r.Header.Get("Accept"); accept != "" && accept != "*/*" {
+		for _, part := range strings.Split(accept, ",") {
+			mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+			if err != nil {
+				continue
+			}
+			if name, ok := mediaTypes[mediaType]; ok {
+				return encoders[name]
+			}
+		}
+	}
+
+	if ct := r.Header.Get("Content-Type"); ct != "" {
+		if mediaType, _, err := mime.ParseMediaType(ct); err == nil {
+			if name, ok := mediaTypes[mediaType]; ok {
+				return encoders[name]
+			}
+		}
+	}
+
+	return encoders["json"]
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestEncodersRoundTrip comprueba que cada Encoder registrado en encoders
+// serializa y vuelve a leer una Note sin perder datos, incluido un
+// LegacyId opcional.
+func TestEncodersRoundTrip(t *testing.T) {
+	legacyId := 42
+	note := Note{Id: "1", Title: "t", Description: "d", LegacyId: &legacyId}
+
+	for name, enc := range encoders {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := enc.Encode(&buf, note); err != nil {
+				t.Fatalf("Encode: %v", err)
+			}
+
+			var got Note
+			if err := enc.Decode(&buf, &got); err != nil {
+				t.Fatalf("Decode: %v", err)
+			}
+
+			if got.Id != note.Id || got.Title != note.Title || got.Description != note.Description {
+				t.Fatalf("round trip = %+v, want %+v", got, note)
+			}
+			if got.LegacyId == nil || *got.LegacyId != *note.LegacyId {
+				t.Fatalf("round trip LegacyId = %v, want %d", got.LegacyId, *note.LegacyId)
+			}
+		})
+	}
+}
+
+// TestEncoderForSelectsByFormatQueryParam comprueba que ?format= tiene
+// prioridad sobre cualquier otra señal.
+func TestEncoderForSelectsByFormatQueryParam(t *testing.T) {
+	r := httptest.NewRequest("GET", "/notes?format=yaml", nil)
+	r.Header.Set("Accept", "application/msgpack")
+
+	if enc := encoderFor(r); enc.ContentType() != "application/yaml" {
+		t.Fatalf("ContentType = %q, want application/yaml", enc.ContentType())
+	}
+}
+
+// TestEncoderForSelectsByAcceptHeader comprueba que, sin ?format=, se usa
+// el primer tipo del header Accept que reconocemos.
+func TestEncoderForSelectsByAcceptHeader(t *testing.T) {
+	r := httptest.NewRequest("GET", "/notes", nil)
+	r.Header.Set("Accept", "text/html, application/msgpack;q=0.9")
+
+	if enc := encoderFor(r); enc.ContentType() != "application/msgpack" {
+		t.Fatalf("ContentType = %q, want application/msgpack", enc.ContentType())
+	}
+}
+
+// TestEncoderForSelectsByContentTypeHeader comprueba que, sin ?format= ni
+// Accept reconocido, se cae al Content-Type de la petición.
+func TestEncoderForSelectsByContentTypeHeader(t *testing.T) {
+	r := httptest.NewRequest("POST", "/notes", nil)
+	r.Header.Set("Content-Type", "application/x-yaml")
+
+	if enc := encoderFor(r); enc.ContentType() != "application/yaml" {
+		t.Fatalf("ContentType = %q, want application/yaml", enc.ContentType())
+	}
+}
+
+// TestEncoderForDefaultsToJSON comprueba que sin ninguna señal reconocida
+// se mantiene JSON, el comportamiento histórico de la API.
+func TestEncoderForDefaultsToJSON(t *testing.T) {
+	r := httptest.NewRequest("GET", "/notes", nil)
+
+	if enc := encoderFor(r); enc.ContentType() != "application/json" {
+		t.Fatalf("ContentType = %q, want application/json", enc.ContentType())
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	EventNoteCreated = "note.created"
+	EventNoteUpdated = "note.updated"
+	EventNoteDeleted = "note.deleted"
+)
+
+// Event describe un cambio sobre una nota, emitido tras cada creación,
+// actualización o borrado.
+type Event struct {
+	Type string    `json:"type"`
+	Note Note      `json:"note"`
+	At   time.Time `json:"at"`
+}
+
+// EventBus reparte cada Event publicado a todos los suscriptores activos en
+// ese momento. No guarda histórico: un suscriptor solo ve los eventos
+// emitidos mientras está suscrito.
+type EventBus struct {
+	mu   sync.Mutex
+	subs map[chan Event]struct{}
+}
+
+func NewEventBus() *EventBus {
+	return &EventBus{subs: make(map[chan Event]struct{})}
+}
+
+// Subscribe registra un canal nuevo y devuelve una función para darlo de
+// baja. El canal tiene buffer para no bloquear Publish si el suscriptor va
+// lento; si se llena, es el evento que se está publicando el que se
+// descarta para ese suscriptor (los ya encolados se mantienen), en lugar de
+// bloquear a los demás.
+func (b *EventBus) Subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, 16)
+
+	b.mu.Lock()
+	b.subs[ch] = struct{}{}
+	b.mu.Unlock()
+
+	unsubscribe := func() {
+		b.mu.Lock()
+		delete(b.subs, ch)
+		b.mu.Unlock()
+		close(ch)
+	}
+	return ch, unsubscribe
+}
+
+func (b *EventBus) Publish(e Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subs {
+		select {
+		case ch <- e:
+		default:
+			// Suscriptor lento: descartamos el evento para no bloquear al resto.
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// addSubscription registra un webhook que recibirá los eventos de notas
+// (POST /subscriptions).
+//
+// TODO: este endpoint todavía no exige autenticación, así que cualquiera
+// puede registrar un webhook; falta decidir el mecanismo antes de exponerlo
+// fuera de un entorno de confianza.
+func (s *server) addSubscription(w http.ResponseWriter, r *http.Request) {
+	enc := encoderFor(r)
+	w.Header().Set("Content-Type", enc.ContentType())
+
+	var body struct {
+		URL string `json:"url" yaml:"url" msgpack:"url"`
+	}
+	if err := enc.Decode(r.Body, &body); err != nil {
+		http.Error(w, "Error invalid payload", http.StatusBadRequest)
+		return
+	}
+
+	if body.URL == "" {
+		http.Error(w, "Invalid url", http.StatusBadRequest)
+		return
+	}
+
+	if err := validateWebhookURL(body.URL); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid url: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	sub, err := s.subs.Add(body.URL)
+	if err != nil {
+		http.Error(w, "Error saving the subscription", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	if err := enc.Encode(w, sub); err != nil {
+		http.Error(w, "Error encoding response.", http.StatusInternalServerError)
+		return
+	}
+}
+
+// validateWebhookURL rechaza los destinos que convertirían el dispatcher de
+// webhooks en un relay SSRF: solo se admite http(s) y el host no puede
+// resolver a loopback, redes privadas, link-local (incluido el servicio de
+// metadatos en la nube, 169.254.169.254) ni a una dirección no especificada.
+//
+// Esto solo protege el alta de la suscripción: como el host se resuelve de
+// nuevo en cada intento de entrega, reutiliza isAllowedIP en
+// webhooks.go para revalidar la IP justo antes de conectar y así cerrar el
+// hueco de DNS rebinding (el dominio cambia de IP entre el alta y el envío).
+func validateWebhookURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("malformed url")
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("scheme must be http or https")
+	}
+
+	host := u.Hostname()
+	if host == "" {
+		return fmt.Errorf("missing host")
+	}
+	if strings.EqualFold(host, "localhost") {
+		return fmt.Errorf("host not allowed")
+	}
+
+	ips, err := net.LookupIP(host)
+	if err != nil {
+		if ip := net.ParseIP(host); ip != nil {
+			ips = []net.IP{ip}
+		} else {
+			return fmt.Errorf("could not resolve host")
+		}
+	}
+	for _, ip := range ips {
+		if !isAllowedIP(ip) {
+			return fmt.Errorf("host not allowed")
+		}
+	}
+	return nil
+}
+
+// isAllowedIP indica si ip es un destino válido para un webhook: ni
+// loopback, ni red privada, ni link-local (incluido el servicio de
+// metadatos en la nube, 169.254.169.254), ni una dirección no especificada.
+func isAllowedIP(ip net.IP) bool {
+	return !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() && !ip.IsUnspecified()
+}
+
+// streamNotes envía los eventos de notas al cliente por Server-Sent Events
+// mientras la conexión siga abierta (GET /notes/stream).
+func (s *server) streamNotes(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	events, unsubscribe := s.bus.Subscribe()
+	defer unsubscribe()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case e, ok := <-events:
+			if !ok {
+				return
+			}
+
+			// Cada evento es una única línea de JSON: el formato SSE requiere un
+			// prefijo "data: " por línea, así que evitamos la indentación.
+			payload, err := json.Marshal(e)
+			if err != nil {
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Type, payload); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
@@ -0,0 +1,328 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+)
+
+// writeOpKind identifica el tipo de mutación aplicada por el goroutine
+// escritor de JSONStore.
+type writeOpKind string
+
+const (
+	opCreate writeOpKind = "create"
+	opUpdate writeOpKind = "update"
+	opDelete writeOpKind = "delete"
+)
+
+// writeOp es una mutación pendiente enviada al goroutine escritor. resultCh
+// recibe el resultado una vez que la operación queda a salvo en el WAL.
+type writeOp struct {
+	kind     writeOpKind
+	id       string
+	note     Note
+	resultCh chan writeResult
+}
+
+type writeResult struct {
+	note Note
+	err  error
+}
+
+// walEntry es la representación en disco de un writeOp ya confirmado,
+// usada tanto para escribir el WAL como para reproducirlo al arrancar.
+type walEntry struct {
+	Kind writeOpKind `json:"kind"`
+	Id   string      `json:"id,omitempty"`
+	Note Note        `json:"note,omitempty"`
+}
+
+// JSONStore persiste las notas en un fichero JSON. Un único goroutine
+// escritor es dueño del slice en memoria (la fuente de verdad) y de los
+// ficheros en disco: las peticiones ya no compiten por leer-modificar-
+// escribir el fichero completo. Cada mutación se registra primero en un
+// write-ahead log (fsync antes de confirmar) y el fichero principal se
+// recompacta con escritura-a-temporal-y-rename, así una caída a mitad de
+// escritura no puede truncar notes.json.
+type JSONStore struct {
+	fileName string
+	walName  string
+	walFile  *os.File
+	ops      chan writeOp
+
+	mu    sync.RWMutex
+	notes []Note
+}
+
+// NewJSONStore carga fileName (y su WAL si quedó alguno pendiente de una
+// caída previa) y arranca el goroutine escritor.
+func NewJSONStore(fileName string) (*JSONStore, error) {
+	s := &JSONStore{
+		fileName: fileName,
+		walName:  fileName + ".wal",
+		ops:      make(chan writeOp, 64),
+	}
+
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+
+	walFile, err := os.OpenFile(s.walName, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+	s.walFile = walFile
+
+	// Si había un WAL pendiente, lo recompactamos ya en notes.json para que
+	// el arranque siguiente sea rápido.
+	if err := s.flush(); err != nil {
+		return nil, err
+	}
+
+	go s.run()
+	return s, nil
+}
+
+func (s *JSONStore) load() error {
+	content, err := os.ReadFile(s.fileName)
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(content, &s.notes); err != nil {
+			return err
+		}
+	case os.IsNotExist(err):
+		s.notes = nil
+	default:
+		return err
+	}
+
+	return s.replayWAL()
+}
+
+func (s *JSONStore) replayWAL() error {
+	f, err := os.Open(s.walName)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry walEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return fmt.Errorf("corrupt WAL entry: %w", err)
+		}
+
+		switch entry.Kind {
+		case opCreate:
+			s.notes = append(s.notes, entry.Note)
+		case opUpdate:
+			if idx, found := findNoteById(entry.Id, &s.notes); found {
+				s.notes[idx].Title = entry.Note.Title
+				s.notes[idx].Description = entry.Note.Description
+			}
+		case opDelete:
+			if idx, found := findNoteById(entry.Id, &s.notes); found {
+				s.notes = deleteNote(idx, &s.notes)
+			}
+		}
+	}
+	return scanner.Err()
+}
+
+// run es el único goroutine que aplica mutaciones al slice en memoria y las
+// persiste. Drena todas las operaciones ya encoladas antes de recompactar
+// el fichero principal, así varias operaciones seguidas comparten un mismo
+// flush en vez de reescribir notes.json una por una.
+func (s *JSONStore) run() {
+	for op := range s.ops {
+		batch := []writeOp{op}
+	drain:
+		for {
+			select {
+			case next, ok := <-s.ops:
+				if !ok {
+					break drain
+				}
+				batch = append(batch, next)
+			default:
+				break drain
+			}
+		}
+
+		results := make([]writeResult, len(batch))
+		for i, op := range batch {
+			results[i] = s.apply(op)
+		}
+
+		if err := s.appendWAL(batch, results); err != nil {
+			for i := range results {
+				if results[i].err == nil {
+					results[i].err = err
+				}
+			}
+		} else if err := s.flush(); err != nil {
+			// El WAL ya está a salvo en disco, así que la próxima recompactación
+			// (o una reproducción del WAL al reiniciar) recuperará este estado
+			// aunque este flush concreto haya fallado.
+			fmt.Fprintf(os.Stderr, "json store: error compacting %s: %v\n", s.fileName, err)
+		}
+
+		for i, op := range batch {
+			op.resultCh <- results[i]
+		}
+	}
+}
+
+func (s *JSONStore) apply(op writeOp) writeResult {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch op.kind {
+	case opCreate:
+		s.notes = append(s.notes, op.note)
+		return writeResult{note: op.note}
+	case opUpdate:
+		idx, found := findNoteById(op.id, &s.notes)
+		if !found {
+			return writeResult{err: ErrNoteNotFound}
+		}
+		s.notes[idx].Title = op.note.Title
+		s.notes[idx].Description = op.note.Description
+		return writeResult{note: s.notes[idx]}
+	case opDelete:
+		idx, found := findNoteById(op.id, &s.notes)
+		if !found {
+			return writeResult{err: ErrNoteNotFound}
+		}
+		s.notes = deleteNote(idx, &s.notes)
+		return writeResult{}
+	default:
+		return writeResult{err: fmt.Errorf("unknown write op %q", op.kind)}
+	}
+}
+
+// appendWAL escribe una línea JSONL por cada operación que se aplicó con
+// éxito y fuerza un fsync antes de devolver, para que ninguna operación se
+// confirme sin estar a salvo en disco.
+func (s *JSONStore) appendWAL(batch []writeOp, results []writeResult) error {
+	w := bufio.NewWriter(s.walFile)
+
+	wrote := false
+	for i, op := range batch {
+		if results[i].err != nil {
+			continue
+		}
+
+		line, err := json.Marshal(walEntry{Kind: op.kind, Id: op.id, Note: op.note})
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(append(line, '\n')); err != nil {
+			return err
+		}
+		wrote = true
+	}
+
+	if !wrote {
+		return nil
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return s.walFile.Sync()
+}
+
+// flush recompacta el slice en memoria en notes.json mediante
+// escritura-a-temporal-y-rename, y vacía el WAL porque ya queda reflejado
+// en el fichero principal.
+func (s *JSONStore) flush() error {
+	s.mu.RLock()
+	data, err := json.MarshalIndent(s.notes, "", "  ")
+	s.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	tmp := s.fileName + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, s.fileName); err != nil {
+		return err
+	}
+
+	if err := s.walFile.Truncate(0); err != nil {
+		return err
+	}
+	_, err = s.walFile.Seek(0, 0)
+	return err
+}
+
+// No devolvemos la nota y así es mejor para usarlo en todos los métodos
+func findNoteById(id string, notes *[]Note) (int, bool) {
+	for i, n := range *notes {
+		if n.Id == id {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+func deleteNote(index int, notes *[]Note) []Note {
+	return slices.Delete(*notes, index, index+1)
+}
+
+func (s *JSONStore) List(opts ListOptions) ([]Note, int, error) {
+	s.mu.RLock()
+	notes := make([]Note, len(s.notes))
+	copy(notes, s.notes)
+	s.mu.RUnlock()
+
+	filtered := filterNotes(notes, opts.Query)
+	sortNotes(filtered, opts.Sort)
+
+	total := len(filtered)
+	return paginate(filtered, opts.Offset, opts.Limit), total, nil
+}
+
+func (s *JSONStore) Get(id string) (Note, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	idx, found := findNoteById(id, &s.notes)
+	if !found {
+		return Note{}, ErrNoteNotFound
+	}
+	return s.notes[idx], nil
+}
+
+// Create persiste note, que ya debe traer el id (UUID) asignado por el
+// llamador.
+func (s *JSONStore) Create(note Note) (Note, error) {
+	res := s.submit(writeOp{kind: opCreate, note: note})
+	return res.note, res.err
+}
+
+func (s *JSONStore) Update(id string, note Note) (Note, error) {
+	res := s.submit(writeOp{kind: opUpdate, id: id, note: note})
+	return res.note, res.err
+}
+
+func (s *JSONStore) Delete(id string) error {
+	res := s.submit(writeOp{kind: opDelete, id: id})
+	return res.err
+}
+
+func (s *JSONStore) submit(op writeOp) writeResult {
+	op.resultCh = make(chan writeResult, 1)
+	s.ops <- op
+	return <-op.resultCh
+}
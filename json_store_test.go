@@ -0,0 +1,58 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestJSONStoreReplaysWALAfterCrash simula una caída justo después de que una
+// mutación quede confirmada en el WAL pero antes de que flush() la
+// recompacte en notes.json: escribimos la entrada directamente en el fichero
+// .wal, sin pasar por el goroutine escritor, y comprobamos que al abrir un
+// JSONStore nuevo sobre el mismo fichero la nota aparece igualmente.
+func TestJSONStoreReplaysWALAfterCrash(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "notes.json")
+
+	store, err := NewJSONStore(fileName)
+	if err != nil {
+		t.Fatalf("NewJSONStore: %v", err)
+	}
+	if _, err := store.Create(Note{Id: "1", Title: "first", Description: "desc"}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	entry := walEntry{Kind: opCreate, Id: "2", Note: Note{Id: "2", Title: "second", Description: "desc"}}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	f, err := os.OpenFile(fileName+".wal", os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("OpenFile wal: %v", err)
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		t.Fatalf("Write wal: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatalf("Close wal: %v", err)
+	}
+
+	restarted, err := NewJSONStore(fileName)
+	if err != nil {
+		t.Fatalf("NewJSONStore after crash: %v", err)
+	}
+
+	_, total, err := restarted.List(ListOptions{Limit: maxLimit})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if total != 2 {
+		t.Fatalf("total = %d, want 2", total)
+	}
+
+	if _, err := restarted.Get("2"); err != nil {
+		t.Fatalf("Get(2) after replay: %v", err)
+	}
+}
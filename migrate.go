@@ -0,0 +1,49 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/google/uuid"
+)
+
+// legacyNote representa una nota tal y como se guardaba antes de adoptar
+// UUIDs como identificador.
+type legacyNote struct {
+	Id          int    `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+// migrateLegacyIds reescribe fileName asignando un UUID a cada nota,
+// conservando su id entero original en legacy_id. Todas las notas del
+// fichero deben usar el esquema antiguo de id entero: si alguna ya tiene un
+// id de texto, devuelve el error de decodificación y no modifica el fichero.
+func migrateLegacyIds(fileName string) error {
+	content, err := os.ReadFile(fileName)
+	if err != nil {
+		return err
+	}
+
+	var legacy []legacyNote
+	if err := json.Unmarshal(content, &legacy); err != nil {
+		return err
+	}
+
+	migrated := make([]Note, 0, len(legacy))
+	for _, n := range legacy {
+		legacyId := n.Id
+		migrated = append(migrated, Note{
+			Id:          uuid.New().String(),
+			Title:       n.Title,
+			Description: n.Description,
+			LegacyId:    &legacyId,
+		})
+	}
+
+	updated, err := json.MarshalIndent(migrated, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(fileName, updated, 0644)
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestMigrateLegacyIdsPreservesOldIds comprueba que migrateLegacyIds
+// sustituye el id entero de cada nota por un UUID, conservando el valor
+// original en LegacyId, y que el resto de campos no cambia.
+func TestMigrateLegacyIdsPreservesOldIds(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "notes.json")
+
+	legacy := []legacyNote{
+		{Id: 1, Title: "first", Description: "desc1"},
+		{Id: 2, Title: "second", Description: "desc2"},
+	}
+	data, err := json.Marshal(legacy)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if err := os.WriteFile(fileName, data, 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	if err := migrateLegacyIds(fileName); err != nil {
+		t.Fatalf("migrateLegacyIds: %v", err)
+	}
+
+	migratedData, err := os.ReadFile(fileName)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	var migrated []Note
+	if err := json.Unmarshal(migratedData, &migrated); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	if len(migrated) != len(legacy) {
+		t.Fatalf("len(migrated) = %d, want %d", len(migrated), len(legacy))
+	}
+	for i, n := range migrated {
+		if n.Id == "" {
+			t.Fatalf("migrated[%d].Id is empty, want a UUID", i)
+		}
+		if n.LegacyId == nil || *n.LegacyId != legacy[i].Id {
+			t.Fatalf("migrated[%d].LegacyId = %v, want %d", i, n.LegacyId, legacy[i].Id)
+		}
+		if n.Title != legacy[i].Title || n.Description != legacy[i].Description {
+			t.Fatalf("migrated[%d] = %+v, want title/description preserved from %+v", i, n, legacy[i])
+		}
+	}
+	if migrated[0].Id == migrated[1].Id {
+		t.Fatalf("migrated notes got the same UUID: %s", migrated[0].Id)
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+)
+
+const (
+	defaultLimit = 20
+	maxLimit     = 100
+)
+
+// ListOptions recoge los parámetros de búsqueda, orden y paginación
+// soportados por GET /notes. La proyección de campos (?fields=) se aplica
+// aparte, sobre la respuesta ya formateada, así que no vive aquí.
+type ListOptions struct {
+	Query  string
+	Sort   string
+	Limit  int
+	Offset int
+}
+
+// filterNotes se queda con las notas cuyo Title o Description contienen q,
+// sin distinguir mayúsculas de minúsculas. Un q vacío no filtra nada.
+func filterNotes(notes []Note, q string) []Note {
+	if q == "" {
+		return notes
+	}
+
+	q = strings.ToLower(q)
+	filtered := make([]Note, 0, len(notes))
+	for _, n := range notes {
+		if strings.Contains(strings.ToLower(n.Title), q) || strings.Contains(strings.ToLower(n.Description), q) {
+			filtered = append(filtered, n)
+		}
+	}
+	return filtered
+}
+
+// sortNotes ordena notes in-place según sort ("id" o "title", con un
+// prefijo "-" para orden descendente). Un valor no reconocido no reordena.
+func sortNotes(notes []Note, sort string) {
+	desc := strings.HasPrefix(sort, "-")
+	field := strings.TrimPrefix(sort, "-")
+
+	var compare func(a, b Note) int
+	switch field {
+	case "id":
+		compare = func(a, b Note) int { return cmp.Compare(a.Id, b.Id) }
+	case "title":
+		compare = func(a, b Note) int { return cmp.Compare(a.Title, b.Title) }
+	default:
+		return
+	}
+
+	slices.SortFunc(notes, func(a, b Note) int {
+		if desc {
+			return compare(b, a)
+		}
+		return compare(a, b)
+	})
+}
+
+// paginate aplica offset/limit sobre notes, ya filtradas y ordenadas.
+func paginate(notes []Note, offset, limit int) []Note {
+	if offset >= len(notes) {
+		return []Note{}
+	}
+
+	end := offset + limit
+	if end > len(notes) {
+		end = len(notes)
+	}
+	return notes[offset:end]
+}
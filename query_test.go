@@ -0,0 +1,61 @@
+package main
+
+import "testing"
+
+func TestFilterNotes(t *testing.T) {
+	notes := []Note{
+		{Id: "1", Title: "Shopping list", Description: "milk and bread"},
+		{Id: "2", Title: "Work", Description: "finish the REPORT"},
+	}
+
+	if got := filterNotes(notes, ""); len(got) != 2 {
+		t.Fatalf("filterNotes with empty query = %d notes, want 2", len(got))
+	}
+
+	got := filterNotes(notes, "report")
+	if len(got) != 1 || got[0].Id != "2" {
+		t.Fatalf("filterNotes(%q) = %+v, want only note 2 (case-insensitive match)", "report", got)
+	}
+}
+
+func TestSortNotes(t *testing.T) {
+	notes := []Note{
+		{Id: "2", Title: "banana"},
+		{Id: "1", Title: "apple"},
+		{Id: "3", Title: "cherry"},
+	}
+
+	sortNotes(notes, "title")
+	if notes[0].Title != "apple" || notes[1].Title != "banana" || notes[2].Title != "cherry" {
+		t.Fatalf("sort by title asc = %+v", notes)
+	}
+
+	sortNotes(notes, "-id")
+	if notes[0].Id != "3" || notes[1].Id != "2" || notes[2].Id != "1" {
+		t.Fatalf("sort by -id = %+v", notes)
+	}
+
+	before := append([]Note{}, notes...)
+	sortNotes(notes, "unknown")
+	for i := range notes {
+		if notes[i] != before[i] {
+			t.Fatalf("sort with an unrecognized field reordered notes: got %+v, want unchanged %+v", notes, before)
+		}
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	notes := []Note{{Id: "1"}, {Id: "2"}, {Id: "3"}}
+
+	if got := paginate(notes, 0, 0); len(got) != 0 {
+		t.Fatalf("paginate with limit=0 = %+v, want empty", got)
+	}
+
+	if got := paginate(notes, 10, 2); len(got) != 0 {
+		t.Fatalf("paginate with offset past the end = %+v, want empty", got)
+	}
+
+	if got := paginate(notes, 1, 10); len(got) != 2 || got[0].Id != "2" || got[1].Id != "3" {
+		t.Fatalf("paginate(offset=1, limit=10) = %+v, want [2 3]", got)
+	}
+}
@@ -0,0 +1,285 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// server agrupa las dependencias que necesitan los handlers: el NoteStore
+// activo, el bus de eventos y el registro de webhooks, en lugar de
+// depender de variables globales.
+type server struct {
+	store NoteStore
+	bus   *EventBus
+	subs  *SubscriptionStore
+}
+
+func newServer(store NoteStore, bus *EventBus, subs *SubscriptionStore) *server {
+	return &server{store: store, bus: bus, subs: subs}
+}
+
+func (s *server) getNotes(w http.ResponseWriter, r *http.Request) {
+	enc := encoderFor(r)
+	w.Header().Set("Content-Type", enc.ContentType())
+
+	opts, fields, err := parseListOptions(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	notes, total, err := s.store.List(opts)
+	if err != nil {
+		http.Error(w, "Error reading the notes.", http.StatusInternalServerError)
+		return
+	}
+
+	setPaginationLinks(w, r, opts, total)
+
+	envelope := map[string]any{
+		"items":  projectFields(notes, fields),
+		"total":  total,
+		"limit":  opts.Limit,
+		"offset": opts.Offset,
+	}
+
+	if err := enc.Encode(w, envelope); err != nil {
+		http.Error(w, "Error encoding response.", http.StatusInternalServerError)
+		return
+	}
+	// No w.WriteHeader(http.StatusOK) porque el encoder lo pone solo
+}
+
+// parseListOptions interpreta los query params de GET /notes: q, limit,
+// offset, sort y fields. limit se acota a maxLimit para no permitir
+// respuestas sin límite.
+func parseListOptions(r *http.Request) (ListOptions, []string, error) {
+	q := r.URL.Query()
+
+	opts := ListOptions{
+		Query: q.Get("q"),
+		Sort:  q.Get("sort"),
+		Limit: defaultLimit,
+	}
+
+	if raw := q.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 0 {
+			return opts, nil, fmt.Errorf("invalid limit %q", raw)
+		}
+		opts.Limit = limit
+	}
+	if opts.Limit > maxLimit {
+		opts.Limit = maxLimit
+	}
+
+	if raw := q.Get("offset"); raw != "" {
+		offset, err := strconv.Atoi(raw)
+		if err != nil || offset < 0 {
+			return opts, nil, fmt.Errorf("invalid offset %q", raw)
+		}
+		opts.Offset = offset
+	}
+
+	var fields []string
+	if raw := q.Get("fields"); raw != "" {
+		fields = strings.Split(raw, ",")
+	}
+
+	return opts, fields, nil
+}
+
+// projectFields se queda solo con los campos pedidos en cada nota. Sin
+// fields, se devuelven las notas tal cual para no pagar el coste de pasar
+// por un map en el caso común.
+func projectFields(notes []Note, fields []string) any {
+	if len(fields) == 0 {
+		return notes
+	}
+
+	allowed := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		allowed[strings.TrimSpace(f)] = true
+	}
+
+	projected := make([]map[string]any, len(notes))
+	for i, n := range notes {
+		full, _ := json.Marshal(n)
+		var m map[string]any
+		_ = json.Unmarshal(full, &m)
+		for k := range m {
+			if !allowed[k] {
+				delete(m, k)
+			}
+		}
+		projected[i] = m
+	}
+	return projected
+}
+
+// setPaginationLinks añade un header Link (RFC 5988) con las URLs next/prev
+// cuando hay más páginas disponibles.
+func setPaginationLinks(w http.ResponseWriter, r *http.Request, opts ListOptions, total int) {
+	var links []string
+
+	if opts.Offset+opts.Limit < total {
+		links = append(links, fmt.Sprintf(`<%s>; rel="next"`, paginationURL(r, opts.Offset+opts.Limit, opts.Limit)))
+	}
+	if opts.Offset > 0 {
+		prevOffset := opts.Offset - opts.Limit
+		if prevOffset < 0 {
+			prevOffset = 0
+		}
+		links = append(links, fmt.Sprintf(`<%s>; rel="prev"`, paginationURL(r, prevOffset, opts.Limit)))
+	}
+
+	if len(links) > 0 {
+		w.Header().Set("Link", strings.Join(links, ", "))
+	}
+}
+
+func paginationURL(r *http.Request, offset, limit int) string {
+	q := r.URL.Query()
+	q.Set("offset", strconv.Itoa(offset))
+	q.Set("limit", strconv.Itoa(limit))
+
+	u := *r.URL
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+func (s *server) getNoteById(w http.ResponseWriter, r *http.Request) {
+	enc := encoderFor(r)
+	w.Header().Set("Content-Type", enc.ContentType())
+
+	id := r.PathValue("id")
+
+	note, err := s.store.Get(id)
+	if err == ErrNoteNotFound {
+		http.Error(w, "Error searching the note.", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error reading the notes.", http.StatusInternalServerError)
+		return
+	}
+
+	if err := enc.Encode(w, note); err != nil {
+		http.Error(w, "Error encoding response.", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (s *server) addNote(w http.ResponseWriter, r *http.Request) {
+	enc := encoderFor(r)
+	w.Header().Set("Content-Type", enc.ContentType())
+
+	var newNote Note
+	if err := enc.Decode(r.Body, &newNote); err != nil {
+		http.Error(w, "Error invalid payload", http.StatusBadRequest)
+		return
+	}
+
+	if newNote.Title == "" || newNote.Description == "" {
+		http.Error(w, "Invalid title or description", http.StatusBadRequest)
+		return
+	}
+
+	// Asignamos el id antes de persistir para no depender del último
+	// elemento almacenado
+	newNote.Id = uuid.New().String()
+
+	created, err := s.store.Create(newNote)
+	if err != nil {
+		http.Error(w, "Error saving the new note", http.StatusInternalServerError)
+		return
+	}
+	s.bus.Publish(Event{Type: EventNoteCreated, Note: created, At: time.Now()})
+
+	w.WriteHeader(http.StatusCreated)
+	res := map[string]string{
+		"message": "Note created successfully.",
+		"id":      created.Id,
+	}
+	if err := enc.Encode(w, res); err != nil {
+		http.Error(w, "Error encoding response.", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (s *server) modifyNote(w http.ResponseWriter, r *http.Request) {
+	enc := encoderFor(r)
+	w.Header().Set("Content-Type", enc.ContentType())
+
+	var updatedNote Note
+
+	// Leemos y comprobamos el body
+	if err := enc.Decode(r.Body, &updatedNote); err != nil {
+		http.Error(w, "Error invalid payload", http.StatusBadRequest)
+		return
+	}
+
+	// Comprobamos el id de la url
+	id := r.PathValue("id")
+
+	updated, err := s.store.Update(id, updatedNote)
+	if err == ErrNoteNotFound {
+		http.Error(w, "Error searching the note.", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error saving the note", http.StatusInternalServerError)
+		return
+	}
+	s.bus.Publish(Event{Type: EventNoteUpdated, Note: updated, At: time.Now()})
+
+	res := map[string]string{
+		"message": "Note updated successfully.",
+		"id":      updated.Id,
+	}
+	if err := enc.Encode(w, res); err != nil {
+		http.Error(w, "Error encoding response.", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (s *server) deleteNoteById(w http.ResponseWriter, r *http.Request) {
+	enc := encoderFor(r)
+	w.Header().Set("Content-Type", enc.ContentType())
+
+	id := r.PathValue("id")
+
+	note, err := s.store.Get(id)
+	if err == ErrNoteNotFound {
+		http.Error(w, "Error searching the note.", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error reading the notes.", http.StatusInternalServerError)
+		return
+	}
+
+	if err := s.store.Delete(id); err == ErrNoteNotFound {
+		http.Error(w, "Error searching the note.", http.StatusNotFound)
+		return
+	} else if err != nil {
+		http.Error(w, "Error saving the note", http.StatusInternalServerError)
+		return
+	}
+	s.bus.Publish(Event{Type: EventNoteDeleted, Note: note, At: time.Now()})
+
+	res := map[string]string{
+		"message": "Note deleted successfully.",
+		"id":      id,
+	}
+	if err := enc.Encode(w, res); err != nil {
+		http.Error(w, "Error encoding response.", http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseListOptionsDefaults(t *testing.T) {
+	r := httptest.NewRequest("GET", "/notes", nil)
+
+	opts, fields, err := parseListOptions(r)
+	if err != nil {
+		t.Fatalf("parseListOptions: %v", err)
+	}
+	if opts.Limit != defaultLimit || opts.Offset != 0 || opts.Query != "" || opts.Sort != "" {
+		t.Fatalf("opts = %+v, want defaults", opts)
+	}
+	if fields != nil {
+		t.Fatalf("fields = %v, want nil", fields)
+	}
+}
+
+func TestParseListOptionsCapsLimitAtMax(t *testing.T) {
+	r := httptest.NewRequest("GET", "/notes?limit=999999", nil)
+
+	opts, _, err := parseListOptions(r)
+	if err != nil {
+		t.Fatalf("parseListOptions: %v", err)
+	}
+	if opts.Limit != maxLimit {
+		t.Fatalf("opts.Limit = %d, want %d", opts.Limit, maxLimit)
+	}
+}
+
+func TestParseListOptionsLimitZeroIsAllowed(t *testing.T) {
+	r := httptest.NewRequest("GET", "/notes?limit=0", nil)
+
+	opts, _, err := parseListOptions(r)
+	if err != nil {
+		t.Fatalf("parseListOptions: %v", err)
+	}
+	if opts.Limit != 0 {
+		t.Fatalf("opts.Limit = %d, want 0", opts.Limit)
+	}
+}
+
+func TestParseListOptionsRejectsNegativeLimitAndOffset(t *testing.T) {
+	for _, raw := range []string{"limit=-1", "offset=-1"} {
+		r := httptest.NewRequest("GET", "/notes?"+raw, nil)
+		if _, _, err := parseListOptions(r); err == nil {
+			t.Fatalf("parseListOptions with %s did not return an error", raw)
+		}
+	}
+}
+
+func TestParseListOptionsParsesFields(t *testing.T) {
+	r := httptest.NewRequest("GET", "/notes?fields=id,title", nil)
+
+	_, fields, err := parseListOptions(r)
+	if err != nil {
+		t.Fatalf("parseListOptions: %v", err)
+	}
+	if len(fields) != 2 || fields[0] != "id" || fields[1] != "title" {
+		t.Fatalf("fields = %v, want [id title]", fields)
+	}
+}
+
+func TestProjectFieldsWithoutFieldsReturnsNotesAsIs(t *testing.T) {
+	notes := []Note{{Id: "1", Title: "t", Description: "d"}}
+
+	got := projectFields(notes, nil)
+	gotNotes, ok := got.([]Note)
+	if !ok || len(gotNotes) != 1 || gotNotes[0] != notes[0] {
+		t.Fatalf("projectFields(nil) = %+v, want notes unchanged", got)
+	}
+}
+
+func TestProjectFieldsKeepsOnlyAllowedKeys(t *testing.T) {
+	notes := []Note{{Id: "1", Title: "t", Description: "d"}}
+
+	got := projectFields(notes, []string{"title", "unknown"})
+	projected, ok := got.([]map[string]any)
+	if !ok || len(projected) != 1 {
+		t.Fatalf("projectFields = %+v, want one projected map", got)
+	}
+	if _, hasTitle := projected[0]["title"]; !hasTitle {
+		t.Fatalf("projected[0] = %+v, want a title key", projected[0])
+	}
+	if len(projected[0]) != 1 {
+		t.Fatalf("projected[0] = %+v, want only the requested, known field", projected[0])
+	}
+}
+
+func TestSetPaginationLinks(t *testing.T) {
+	r := httptest.NewRequest("GET", "/notes?limit=10&offset=10", nil)
+	w := httptest.NewRecorder()
+
+	setPaginationLinks(w, r, ListOptions{Limit: 10, Offset: 10}, 25)
+
+	link := w.Header().Get("Link")
+	if link == "" {
+		t.Fatal("Link header not set, want next and prev rels")
+	}
+	if !strings.Contains(link, `rel="next"`) || !strings.Contains(link, `rel="prev"`) {
+		t.Fatalf("Link = %q, want both next and prev rels", link)
+	}
+}
+
+func TestSetPaginationLinksOmitsNextOnLastPage(t *testing.T) {
+	r := httptest.NewRequest("GET", "/notes?limit=10&offset=20", nil)
+	w := httptest.NewRecorder()
+
+	setPaginationLinks(w, r, ListOptions{Limit: 10, Offset: 20}, 25)
+
+	link := w.Header().Get("Link")
+	if strings.Contains(link, `rel="next"`) {
+		t.Fatalf("Link = %q, should not contain a next rel on the last page", link)
+	}
+	if !strings.Contains(link, `rel="prev"`) {
+		t.Fatalf("Link = %q, want a prev rel", link)
+	}
+}
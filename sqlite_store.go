@@ -0,0 +1,177 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// SQLiteStore persiste las notas en SQLite. A diferencia de JSONStore, cada
+// mutación es una sentencia SQL puntual en lugar de reescribir todo el
+// fichero, que es el cuello de botella del backend de JSON al crecer.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteStore abre (o crea) la base de datos en dsn y garantiza que
+// exista la tabla notes.
+func NewSQLiteStore(dsn string) (*SQLiteStore, error) {
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	schema := `CREATE TABLE IF NOT EXISTS notes (
+		id TEXT PRIMARY KEY,
+		title TEXT NOT NULL,
+		description TEXT NOT NULL,
+		legacy_id INTEGER,
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL
+	)`
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &SQLiteStore{db: db}, nil
+}
+
+func scanNote(row interface{ Scan(...any) error }) (Note, error) {
+	var n Note
+	var legacyId sql.NullInt64
+	if err := row.Scan(&n.Id, &n.Title, &n.Description, &legacyId); err != nil {
+		return Note{}, err
+	}
+	if legacyId.Valid {
+		v := int(legacyId.Int64)
+		n.LegacyId = &v
+	}
+	return n, nil
+}
+
+// List traduce opts directamente a SQL: el filtro, el orden y la
+// paginación los resuelve la base de datos, no Go.
+func (s *SQLiteStore) List(opts ListOptions) ([]Note, int, error) {
+	where := ""
+	args := []any{}
+	if opts.Query != "" {
+		where = "WHERE LOWER(title) LIKE LOWER(?) OR LOWER(description) LIKE LOWER(?)"
+		like := "%" + opts.Query + "%"
+		args = append(args, like, like)
+	}
+
+	var total int
+	countQuery := fmt.Sprintf(`SELECT COUNT(*) FROM notes %s`, where)
+	if err := s.db.QueryRow(countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := fmt.Sprintf(
+		`SELECT id, title, description, legacy_id FROM notes %s ORDER BY %s LIMIT ? OFFSET ?`,
+		where, sqlOrderBy(opts.Sort),
+	)
+	rows, err := s.db.Query(query, append(args, opts.Limit, opts.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var notes []Note
+	for rows.Next() {
+		n, err := scanNote(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		notes = append(notes, n)
+	}
+	return notes, total, rows.Err()
+}
+
+// sqlOrderBy traduce sort a una cláusula ORDER BY. Solo acepta nombres de
+// columna conocidos, así que no hay riesgo de inyección al concatenarla.
+func sqlOrderBy(sort string) string {
+	desc := strings.HasPrefix(sort, "-")
+	field := strings.TrimPrefix(sort, "-")
+
+	column := "rowid"
+	switch field {
+	case "id":
+		column = "id"
+	case "title":
+		column = "title"
+	}
+
+	if desc {
+		return column + " DESC"
+	}
+	return column + " ASC"
+}
+
+func (s *SQLiteStore) Get(id string) (Note, error) {
+	row := s.db.QueryRow(`SELECT id, title, description, legacy_id FROM notes WHERE id = ?`, id)
+	n, err := scanNote(row)
+	if err == sql.ErrNoRows {
+		return Note{}, ErrNoteNotFound
+	}
+	return n, err
+}
+
+// Create persiste note, que ya debe traer el id (UUID) asignado por el
+// llamador.
+func (s *SQLiteStore) Create(note Note) (Note, error) {
+	now := time.Now()
+
+	var legacyId any
+	if note.LegacyId != nil {
+		legacyId = *note.LegacyId
+	}
+
+	_, err := s.db.Exec(
+		`INSERT INTO notes (id, title, description, legacy_id, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		note.Id, note.Title, note.Description, legacyId, now, now,
+	)
+	if err != nil {
+		return Note{}, err
+	}
+	return note, nil
+}
+
+func (s *SQLiteStore) Update(id string, note Note) (Note, error) {
+	res, err := s.db.Exec(
+		`UPDATE notes SET title = ?, description = ?, updated_at = ? WHERE id = ?`,
+		note.Title, note.Description, time.Now(), id,
+	)
+	if err != nil {
+		return Note{}, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return Note{}, err
+	}
+	if affected == 0 {
+		return Note{}, ErrNoteNotFound
+	}
+
+	return s.Get(id)
+}
+
+func (s *SQLiteStore) Delete(id string) error {
+	res, err := s.db.Exec(`DELETE FROM notes WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNoteNotFound
+	}
+	return nil
+}
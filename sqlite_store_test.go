@@ -0,0 +1,100 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// TestSQLiteStoreCRUD comprueba el ciclo de vida básico de SQLiteStore:
+// Create persiste la nota (incluido legacy_id), Update solo toca
+// título/descripción, y Get/Delete devuelven ErrNoteNotFound para un id que
+// no existe.
+func TestSQLiteStoreCRUD(t *testing.T) {
+	dsn := filepath.Join(t.TempDir(), "notes.db")
+	store, err := NewSQLiteStore(dsn)
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+
+	legacyId := 7
+	created, err := store.Create(Note{Id: "1", Title: "a", Description: "b", LegacyId: &legacyId})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if created.LegacyId == nil || *created.LegacyId != legacyId {
+		t.Fatalf("created.LegacyId = %v, want %d", created.LegacyId, legacyId)
+	}
+
+	got, err := store.Get("1")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got.LegacyId == nil || *got.LegacyId != legacyId {
+		t.Fatalf("Get.LegacyId = %v, want %d", got.LegacyId, legacyId)
+	}
+
+	updated, err := store.Update("1", Note{Title: "a2", Description: "b2"})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if updated.Title != "a2" || updated.Description != "b2" {
+		t.Fatalf("Update = %+v, want title/description a2/b2", updated)
+	}
+	if updated.LegacyId == nil || *updated.LegacyId != legacyId {
+		t.Fatalf("Update should preserve LegacyId, got %v", updated.LegacyId)
+	}
+
+	if _, err := store.Update("missing", Note{Title: "x", Description: "y"}); err != ErrNoteNotFound {
+		t.Fatalf("Update(missing) = %v, want ErrNoteNotFound", err)
+	}
+
+	if err := store.Delete("1"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := store.Get("1"); err != ErrNoteNotFound {
+		t.Fatalf("Get after delete = %v, want ErrNoteNotFound", err)
+	}
+	if err := store.Delete("1"); err != ErrNoteNotFound {
+		t.Fatalf("Delete(already deleted) = %v, want ErrNoteNotFound", err)
+	}
+}
+
+// TestSQLiteStoreListFiltersSortsAndPaginates comprueba que List traduce
+// opts a SQL: filtra por Query, ordena por Sort y recorta por
+// Limit/Offset, devolviendo el total sin recortar.
+func TestSQLiteStoreListFiltersSortsAndPaginates(t *testing.T) {
+	dsn := filepath.Join(t.TempDir(), "notes.db")
+	store, err := NewSQLiteStore(dsn)
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+
+	for _, n := range []Note{
+		{Id: "1", Title: "banana", Description: "yellow"},
+		{Id: "2", Title: "apple", Description: "red"},
+		{Id: "3", Title: "cherry", Description: "red"},
+	} {
+		if _, err := store.Create(n); err != nil {
+			t.Fatalf("Create(%s): %v", n.Id, err)
+		}
+	}
+
+	notes, total, err := store.List(ListOptions{Sort: "title", Limit: 2})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if total != 3 {
+		t.Fatalf("total = %d, want 3", total)
+	}
+	if len(notes) != 2 || notes[0].Title != "apple" || notes[1].Title != "banana" {
+		t.Fatalf("notes = %+v, want [apple banana]", notes)
+	}
+
+	notes, total, err = store.List(ListOptions{Query: "red", Sort: "title", Limit: 10})
+	if err != nil {
+		t.Fatalf("List with query: %v", err)
+	}
+	if total != 2 || len(notes) != 2 || notes[0].Title != "apple" || notes[1].Title != "cherry" {
+		t.Fatalf("notes = %+v, total = %d, want [apple cherry] / 2", notes, total)
+	}
+}
@@ -0,0 +1,28 @@
+package main
+
+import "errors"
+
+// ErrNoteNotFound se devuelve por un NoteStore cuando no existe ninguna nota
+// con el id solicitado.
+var ErrNoteNotFound = errors.New("note not found")
+
+type Note struct {
+	Id          string `json:"id" yaml:"id" msgpack:"id"`
+	Title       string `json:"title" yaml:"title" msgpack:"title"`
+	Description string `json:"description" yaml:"description" msgpack:"description"`
+	// LegacyId conserva el antiguo id entero de las notas migradas desde el
+	// esquema anterior. Vacío para las notas creadas directamente con UUID.
+	LegacyId *int `json:"legacy_id,omitempty" yaml:"legacy_id,omitempty" msgpack:"legacy_id,omitempty"`
+}
+
+// NoteStore abstrae la persistencia de las notas para poder tener varios
+// backends (fichero JSON, SQLite, ...) detrás de la misma interfaz.
+type NoteStore interface {
+	// List devuelve las notas que cumplen opts, ya ordenadas y paginadas,
+	// junto con el total de coincidencias antes de aplicar Limit/Offset.
+	List(opts ListOptions) (notes []Note, total int, err error)
+	Get(id string) (Note, error)
+	Create(note Note) (Note, error)
+	Update(id string, note Note) (Note, error)
+	Delete(id string) error
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// Subscription es un webhook registrado para recibir eventos de notas.
+type Subscription struct {
+	Id  string `json:"id" yaml:"id" msgpack:"id"`
+	URL string `json:"url" yaml:"url" msgpack:"url"`
+}
+
+// SubscriptionStore persiste los webhooks registrados en un fichero JSON
+// para que sobrevivan a un reinicio del servidor.
+type SubscriptionStore struct {
+	fileName string
+	mu       sync.Mutex
+}
+
+func NewSubscriptionStore(fileName string) *SubscriptionStore {
+	return &SubscriptionStore{fileName: fileName}
+}
+
+func (s *SubscriptionStore) readAll() ([]Subscription, error) {
+	content, err := os.ReadFile(s.fileName)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var subs []Subscription
+	if err := json.Unmarshal(content, &subs); err != nil {
+		return nil, err
+	}
+	return subs, nil
+}
+
+func (s *SubscriptionStore) List() ([]Subscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.readAll()
+}
+
+func (s *SubscriptionStore) Add(url string) (Subscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	subs, err := s.readAll()
+	if err != nil {
+		return Subscription{}, err
+	}
+
+	sub := Subscription{Id: uuid.New().String(), URL: url}
+	subs = append(subs, sub)
+
+	data, err := json.MarshalIndent(subs, "", "  ")
+	if err != nil {
+		return Subscription{}, err
+	}
+
+	// Escritura-a-temporal-y-rename, igual que deliveries.json y notes.json,
+	// para que una caída a mitad de escritura no trunque subscriptions.json.
+	tmp := s.fileName + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return Subscription{}, err
+	}
+	if err := os.Rename(tmp, s.fileName); err != nil {
+		return Subscription{}, err
+	}
+	return sub, nil
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// maxDeliveryAttempts acota los reintentos de un webhook antes de darlo por
+// perdido.
+const maxDeliveryAttempts = 6
+
+// WebhookDispatcher escucha el EventBus y entrega cada evento a los
+// webhooks registrados en subs, reintentando con backoff exponencial ante
+// fallos. Las entregas pendientes se persisten para sobrevivir a un
+// reinicio del servidor.
+type WebhookDispatcher struct {
+	subs   *SubscriptionStore
+	queue  *deliveryQueue
+	secret string
+	client *http.Client
+}
+
+func NewWebhookDispatcher(subs *SubscriptionStore, queueFile, secret string) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		subs:   subs,
+		queue:  newDeliveryQueue(queueFile),
+		secret: secret,
+		client: &http.Client{
+			Timeout:   10 * time.Second,
+			Transport: &http.Transport{DialContext: dialValidated},
+		},
+	}
+}
+
+// dialValidated resuelve addr y solo conecta a una IP que pase isAllowedIP,
+// en vez de dejar que el Transport use la resolución por defecto: como el
+// host de una entrega persistida se resuelve de nuevo en cada reintento (a
+// veces minutos después del alta, o tras un reinicio), validar la URL solo
+// al registrar la suscripción no basta para evitar DNS rebinding hacia una
+// IP interna.
+func dialValidated(ctx context.Context, network, addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	ips, err := net.DefaultResolver.LookupIP(ctx, "ip", host)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ip := range ips {
+		if !isAllowedIP(ip) {
+			return nil, fmt.Errorf("webhooks: refusing to dial disallowed address %s", ip)
+		}
+	}
+
+	var dialer net.Dialer
+	return dialer.DialContext(ctx, network, net.JoinHostPort(ips[0].String(), port))
+}
+
+// Run reanuda las entregas que quedaron pendientes de un reinicio anterior
+// y luego se queda escuchando events hasta que el canal se cierra. Debe
+// lanzarse en su propio goroutine.
+func (d *WebhookDispatcher) Run(events <-chan Event) {
+	for _, del := range d.queue.pending() {
+		go d.attempt(del)
+	}
+
+	for e := range events {
+		subs, err := d.subs.List()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "webhooks: error listing subscriptions: %v\n", err)
+			continue
+		}
+
+		for _, sub := range subs {
+			del := delivery{Id: uuid.New().String(), URL: sub.URL, Event: e}
+			if err := d.queue.add(del); err != nil {
+				fmt.Fprintf(os.Stderr, "webhooks: error queuing delivery: %v\n", err)
+				continue
+			}
+			go d.attempt(del)
+		}
+	}
+}
+
+// attempt entrega del.Event y reintenta con backoff exponencial hasta
+// maxDeliveryAttempts. La entrega se quita de la cola tanto si tiene éxito
+// como si agota los reintentos.
+func (d *WebhookDispatcher) attempt(del delivery) {
+	for del.Attempt < maxDeliveryAttempts {
+		if del.Attempt > 0 {
+			time.Sleep(backoff(del.Attempt))
+		}
+
+		if err := d.send(del); err == nil {
+			d.queue.remove(del.Id)
+			return
+		}
+
+		del.Attempt++
+		d.queue.update(del)
+	}
+
+	d.queue.remove(del.Id)
+	fmt.Fprintf(os.Stderr, "webhooks: giving up on delivery %s to %s after %d attempts\n", del.Id, del.URL, del.Attempt)
+}
+
+func backoff(attempt int) time.Duration {
+	return time.Duration(1<<attempt) * time.Second
+}
+
+func (d *WebhookDispatcher) send(del delivery) error {
+	body, err := json.Marshal(del.Event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, del.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Notes-Signature", sign(d.secret, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// sign calcula el HMAC-SHA256 de body con secret, tal y como deben
+// verificarlo los receptores del webhook en X-Notes-Signature.
+func sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
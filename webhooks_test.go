@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestWebhookDispatcherAttemptRetriesUntilSuccess comprueba que attempt
+// reintenta una entrega que falla al principio y la da por completada (y la
+// quita de la cola persistida) en cuanto el receptor responde con éxito.
+//
+// Usa un *http.Client sin el DialContext validado: httptest.Server escucha
+// en 127.0.0.1, que dialValidated rechazaría, y aquí solo nos interesa el
+// bucle de reintentos, no la validación SSRF (cubierta aparte en
+// TestDialValidatedRejectsDisallowedAddresses).
+func TestWebhookDispatcherAttemptRetriesUntilSuccess(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	queueFile := filepath.Join(t.TempDir(), "deliveries.json")
+	dispatcher := NewWebhookDispatcher(NewSubscriptionStore(filepath.Join(t.TempDir(), "subs.json")), queueFile, "test-secret")
+	dispatcher.client = &http.Client{Timeout: 10 * time.Second}
+
+	del := delivery{Id: "d1", URL: server.URL, Event: Event{Type: EventNoteCreated, Note: Note{Id: "1"}}}
+	if err := dispatcher.queue.add(del); err != nil {
+		t.Fatalf("queue.add: %v", err)
+	}
+
+	dispatcher.attempt(del)
+
+	if got := calls.Load(); got != 2 {
+		t.Fatalf("server received %d calls, want 2", got)
+	}
+
+	pending := dispatcher.queue.pending()
+	if len(pending) != 0 {
+		t.Fatalf("pending deliveries = %d, want 0 after a successful retry", len(pending))
+	}
+}
+
+// TestDialValidatedRejectsDisallowedAddresses comprueba que dialValidated
+// revalida la IP en el momento de conectar y no solo al registrar la
+// suscripción: aunque una URL haya pasado validateWebhookURL, un dial hacia
+// loopback/red privada (p.ej. tras un DNS rebinding) debe fallar.
+func TestDialValidatedRejectsDisallowedAddresses(t *testing.T) {
+	_, err := dialValidated(context.Background(), "tcp", "127.0.0.1:9")
+	if err == nil {
+		t.Fatal("dialValidated accepted a loopback address, want error")
+	}
+}